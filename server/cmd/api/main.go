@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/ManadaHerath/realtime-grid-server/internal/api"
+	"github.com/ManadaHerath/realtime-grid-server/internal/auth"
 	"github.com/ManadaHerath/realtime-grid-server/internal/grid"
 )
 
@@ -16,15 +20,33 @@ func main() {
 	redisPass := getenv("REDIS_PASSWORD", "")
 	redisDB := 0
 
-	store := grid.NewRedisStore(redisAddr, redisPass, redisDB)
-
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     redisAddr,
 		Password: redisPass,
 		DB:       redisDB,
 	})
 
-	apiHandler := api.NewAPI(store, rdb)
+	backing, err := newBackingStore(redisAddr, redisPass, redisDB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheSize := getenvInt("GRID_CACHE_SIZE", 1024)
+	cacheTTL := getenvDuration("GRID_CACHE_TTL", 5*time.Second)
+	store := grid.NewLayeredStore(context.Background(), backing, rdb, cacheSize, cacheTTL)
+
+	authenticator, err := newAuthenticator(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	wsConfig := api.WSConfig{
+		ReadDeadline:  getenvDuration("GRID_WS_READ_DEADLINE", 0),
+		WriteDeadline: getenvDuration("GRID_WS_WRITE_DEADLINE", 0),
+		PingInterval:  getenvDuration("GRID_WS_PING_INTERVAL", 0),
+		SendBuffer:    getenvInt("GRID_WS_SEND_BUFFER", 0),
+	}
+	apiHandler := api.NewAPI(store, rdb, authenticator, wsConfig)
+	go apiHandler.StartReservationSweeper(context.Background())
 
 	mux := http.NewServeMux()
 	apiHandler.RegisterRoutes(mux)
@@ -37,6 +59,49 @@ func main() {
 	}
 }
 
+// newBackingStore builds the durable Store selected by GRID_STORE_BACKEND
+// ("redis", the default single-hash-per-grid layout; "chunked" for grids
+// too large for one hash, see grid.ChunkedRedisStore). GRID_CHUNK_SIZE
+// configures the chunk dimensions for "chunked" as a CoordKey-style
+// "x:y:..." coordinate; a missing or zero entry per dimension falls back
+// to the store's own default.
+func newBackingStore(addr, password string, db int) (grid.Store, error) {
+	switch getenv("GRID_STORE_BACKEND", "redis") {
+	case "chunked":
+		chunkSize, err := grid.ParseCoordKey(getenv("GRID_CHUNK_SIZE", ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRID_CHUNK_SIZE: %w", err)
+		}
+		return grid.NewChunkedRedisStore(addr, password, db, chunkSize), nil
+	default:
+		return grid.NewRedisStore(addr, password, db), nil
+	}
+}
+
+// newAuthenticator builds the caller-identity checker selected by
+// GRID_AUTH_MODE ("noop", the default; "token" for a shared-secret
+// bearer token; "oidc" for OIDC ID-token verification).
+func newAuthenticator(ctx context.Context) (auth.Authenticator, error) {
+	switch getenv("GRID_AUTH_MODE", "noop") {
+	case "token":
+		secret := getenv("GRID_AUTH_TOKEN_SECRET", "")
+		if secret == "" {
+			return nil, fmt.Errorf("GRID_AUTH_TOKEN_SECRET is required when GRID_AUTH_MODE=token")
+		}
+		return auth.NewTokenAuthenticator(secret), nil
+	case "oidc":
+		issuer := getenv("GRID_AUTH_OIDC_ISSUER", "")
+		clientID := getenv("GRID_AUTH_OIDC_CLIENT_ID", "")
+		usernameClaim := getenv("GRID_AUTH_OIDC_USERNAME_CLAIM", "sub")
+		if issuer == "" || clientID == "" {
+			return nil, fmt.Errorf("GRID_AUTH_OIDC_ISSUER and GRID_AUTH_OIDC_CLIENT_ID are required when GRID_AUTH_MODE=oidc")
+		}
+		return auth.NewOIDCAuthenticator(ctx, issuer, clientID, usernameClaim)
+	default:
+		return auth.NoopAuthenticator{}, nil
+	}
+}
+
 func getenv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -44,6 +109,30 @@ func getenv(key, def string) string {
 	return def
 }
 
+func getenvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+func getenvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -5,18 +5,66 @@ import (
     "encoding/json"
     "net/http"
     "strings"
+    "time"
 
     "github.com/redis/go-redis/v9"
+    "github.com/ManadaHerath/realtime-grid-server/internal/auth"
     "github.com/ManadaHerath/realtime-grid-server/internal/grid"
+    "github.com/ManadaHerath/realtime-grid-server/utils"
 )
 
+const defaultReservationTTL = 30 * time.Second
+
+// WSConfig tunes the per-connection WebSocket behavior a *API enforces.
+// A zero-valued field falls back to the package default, so callers
+// that don't care about a given knob can leave it unset.
+type WSConfig struct {
+    ReadDeadline  time.Duration
+    WriteDeadline time.Duration
+    PingInterval  time.Duration
+    SendBuffer    int
+}
+
+func (c WSConfig) withDefaults() WSConfig {
+    if c.ReadDeadline <= 0 {
+        c.ReadDeadline = defaultWSReadDeadline
+    }
+    if c.WriteDeadline <= 0 {
+        c.WriteDeadline = defaultWSWriteDeadline
+    }
+    if c.PingInterval <= 0 {
+        c.PingInterval = defaultWSPingInterval
+    }
+    if c.SendBuffer <= 0 {
+        c.SendBuffer = defaultWSSendBuffer
+    }
+    return c
+}
+
 type API struct {
-    Store grid.Store
-    Redis *redis.Client
+    Store         grid.Store
+    Redis         *redis.Client
+    Authenticator auth.Authenticator
+    WS            WSConfig
 }
 
-func NewAPI(store grid.Store, rdb *redis.Client) *API {
-    return &API{Store: store, Redis: rdb}
+func NewAPI(store grid.Store, rdb *redis.Client, authenticator auth.Authenticator, ws WSConfig) *API {
+    if authenticator == nil {
+        authenticator = auth.NoopAuthenticator{}
+    }
+    return &API{Store: store, Redis: rdb, Authenticator: authenticator, WS: ws.withDefaults()}
+}
+
+// authenticate resolves the caller identity for r, writing a 401
+// response and returning ok=false if the request carries no usable
+// credential.
+func (api *API) authenticate(w http.ResponseWriter, r *http.Request) (owner string, ok bool) {
+    owner, err := api.Authenticator.Authenticate(r)
+    if err != nil {
+        writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+        return "", false
+    }
+    return owner, true
 }
 
 func writeJSON(w http.ResponseWriter, status int, v interface{}) {
@@ -59,27 +107,101 @@ type ClaimCellResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
+type ClaimBatchRequest struct {
+	Cells []grid.CellOp `json:"cells"`
+}
+
+type ClaimBatchResponse struct {
+	Success bool              `json:"success"`
+	Cells   []grid.CellResult `json:"cells"`
+	Error   string            `json:"error,omitempty"`
+}
+
 type CellUpdateEvent struct {
-    Type   string      `json:"type"`
-    GridID string      `json:"gridId"`
-    Coord  []int       `json:"coord,omitempty"`
-    Value  interface{} `json:"value,omitempty"`
+    Type      string      `json:"type"`
+    GridID    string      `json:"gridId"`
+    Coord     []int       `json:"coord,omitempty"`
+    Value     interface{} `json:"value,omitempty"`
+    Owner     string      `json:"owner,omitempty"`
+    ExpiresAt *time.Time  `json:"expiresAt,omitempty"`
+    Seq       int64       `json:"seq,omitempty"`
+    Cells     []grid.CellOp `json:"cells,omitempty"`
 }
 
 type ReleaseCellRequest struct {
     Coord []int `json:"coord"`
 }
 
-func (api *API) publishCellEvent(ctx context.Context, ev CellUpdateEvent) {
+type ReserveCellRequest struct {
+    Coord []int `json:"coord"`
+    Value interface{} `json:"value,omitempty"`
+    TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
+
+type ReservationResponse struct {
+    Success        bool        `json:"success"`
+    ExpiresAt      time.Time   `json:"expiresAt,omitempty"`
+    TentativeValue interface{} `json:"tentativeValue,omitempty"`
+    Error          string      `json:"error,omitempty"`
+}
+
+type RenewReservationRequest struct {
+    Coord []int `json:"coord"`
+    TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
+
+func seqKey(gridID string) string {
+    return "grid:" + gridID + ":seq"
+}
+
+func eventsChannel(gridID string) string {
+    return "grid:" + gridID + ":events"
+}
+
+// eventChannels resolves the channel(s) an event touching coords should
+// be published to. Chunked stores get one channel per distinct chunk
+// the coords fall into, so a client watching only part of the grid
+// isn't woken for writes elsewhere in it; other stores (and events
+// published with no coords) fall back to the single whole-grid channel.
+func (api *API) eventChannels(gridID string, coords ...[]int) []string {
+    chunked, ok := grid.AsChunked(api.Store)
+    if !ok || len(coords) == 0 {
+        return []string{eventsChannel(gridID)}
+    }
+
+    channels, err := chunked.ChunkChannelsForCoords(gridID, coords)
+    if err != nil {
+        return []string{eventsChannel(gridID)}
+    }
+    return channels
+}
+
+func coordsOf(ops []grid.CellOp) [][]int {
+    coords := make([][]int, len(ops))
+    for i, op := range ops {
+        coords[i] = op.Coord
+    }
+    return coords
+}
+
+func (api *API) publishCellEvent(ctx context.Context, ev CellUpdateEvent, coords ...[]int) {
     if api.Redis == nil {
         return
     }
+
+    seq, err := api.Redis.Incr(ctx, seqKey(ev.GridID)).Result()
+    if err != nil {
+        return
+    }
+    ev.Seq = seq
+
     data, err := json.Marshal(ev)
     if err != nil {
         return
     }
-    channel := "grid:" + ev.GridID + ":events"
-    api.Redis.Publish(ctx, channel, data)
+    for _, channel := range api.eventChannels(ev.GridID, coords...) {
+        api.Redis.Publish(ctx, channel, data)
+    }
 }
 
 func (api *API) publishCellUpdate(ctx context.Context, gridID string, coord []int, value interface{}) {
@@ -99,8 +221,7 @@ func (api *API) publishCellUpdate(ctx context.Context, gridID string, coord []in
 		return
 	}
 
-	channel := "grid:" + gridID + ":events"
-	api.Redis.Publish(ctx, channel, data)
+	api.Redis.Publish(ctx, eventsChannel(gridID), data)
 }
 
 func (api *API) HandleCreateGrid(w http.ResponseWriter, r *http.Request) {
@@ -176,7 +297,22 @@ func (api *API) HandleClaimCell(w http.ResponseWriter, r *http.Request, gridID s
 		return
 	}
 
-	err := api.Store.SetCell(gridID, req.Coord, req.Value)
+	owner, ok := api.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	// A caller with an active reservation on this cell (owner or
+	// anonymous) finalizes it instead of racing a fresh SetCell, which
+	// would see the reservation key and reject the write outright.
+	// Anyone without one -- including a caller who collides with a
+	// reservation somebody else holds -- falls back to SetCell, which
+	// reports the correct "cell has an active reservation" conflict
+	// rather than FinalizeCell's ownership-mismatch error.
+	err := api.Store.FinalizeCell(gridID, req.Coord, owner, req.Value)
+	if err == grid.ErrReservationNotFound || err == grid.ErrNotReservationOwner {
+		err = api.Store.SetCell(gridID, req.Coord, req.Value, owner)
+	}
 	if err == grid.ErrGridNotFound {
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "grid not found"})
 		return
@@ -193,6 +329,22 @@ func (api *API) HandleClaimCell(w http.ResponseWriter, r *http.Request, gridID s
 		writeJSON(w, http.StatusConflict, resp)
 		return
 	}
+	if err == grid.ErrCellReserved {
+		resp := ClaimCellResponse{
+			Success: false,
+			Error:   "cell has an active reservation",
+		}
+		writeJSON(w, http.StatusConflict, resp)
+		return
+	}
+	if err == grid.ErrNotReservationOwner {
+		resp := ClaimCellResponse{
+			Success: false,
+			Error:   "reservation held by a different owner",
+		}
+		writeJSON(w, http.StatusForbidden, resp)
+		return
+	}
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
 		return
@@ -204,13 +356,179 @@ func (api *API) HandleClaimCell(w http.ResponseWriter, r *http.Request, gridID s
 		GridID: gridID,
 		Coord:  req.Coord,
 		Value:  req.Value,
-	})
+		Owner:  owner,
+	}, req.Coord)
 
 	resp := ClaimCellResponse{Success: true}
 	writeJSON(w, http.StatusOK, resp)
 }
 
+func (api *API) HandleClaimBatch(w http.ResponseWriter, r *http.Request, gridID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req ClaimBatchRequest
+	if err := parseJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	if len(req.Cells) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "cells required"})
+		return
+	}
+
+	owner, ok := api.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := api.Store.SetCells(gridID, req.Cells, owner)
+	if err == grid.ErrGridNotFound {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "grid not found"})
+		return
+	}
+	if err == grid.ErrDimensionMismatch || err == grid.ErrOutOfBounds || err == grid.ErrDuplicateCoord {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err == grid.ErrBatchClaimFailed {
+		writeJSON(w, http.StatusConflict, ClaimBatchResponse{Success: false, Cells: results, Error: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+
+	api.publishCellEvent(r.Context(), CellUpdateEvent{
+		Type:   "cells_claimed",
+		GridID: gridID,
+		Cells:  req.Cells,
+		Owner:  owner,
+	}, coordsOf(req.Cells)...)
+
+	writeJSON(w, http.StatusOK, ClaimBatchResponse{Success: true, Cells: results})
+}
+
+func (api *API) HandleReserveCell(w http.ResponseWriter, r *http.Request, gridID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req ReserveCellRequest
+	if err := parseJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	if len(req.Coord) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "coord required"})
+		return
+	}
+
+	owner, ok := api.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	ttl := defaultReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	res, err := api.Store.ReserveCell(gridID, req.Coord, owner, req.Value, ttl)
+	if err == grid.ErrGridNotFound {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "grid not found"})
+		return
+	}
+	if err == grid.ErrDimensionMismatch || err == grid.ErrOutOfBounds {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err == grid.ErrCellAlreadySet {
+		writeJSON(w, http.StatusConflict, ReservationResponse{Success: false, Error: "cell already set"})
+		return
+	}
+	if err == grid.ErrCellReserved {
+		writeJSON(w, http.StatusConflict, ReservationResponse{Success: false, Error: "cell has an active reservation"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+
+	expiresAt := res.ExpiresAt
+	api.publishCellEvent(r.Context(), CellUpdateEvent{
+		Type:      "cell_reserved",
+		GridID:    gridID,
+		Coord:     req.Coord,
+		Value:     res.TentativeValue,
+		Owner:     owner,
+		ExpiresAt: &expiresAt,
+	}, req.Coord)
+
+	writeJSON(w, http.StatusOK, ReservationResponse{Success: true, ExpiresAt: res.ExpiresAt, TentativeValue: res.TentativeValue})
+}
+
+func (api *API) HandleRenewReservation(w http.ResponseWriter, r *http.Request, gridID string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req RenewReservationRequest
+	if err := parseJSON(r, &req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON"})
+		return
+	}
+
+	if len(req.Coord) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "coord required"})
+		return
+	}
+
+	owner, ok := api.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	ttl := defaultReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	res, err := api.Store.RenewReservation(gridID, req.Coord, owner, ttl)
+	if err == grid.ErrGridNotFound {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "grid not found"})
+		return
+	}
+	if err == grid.ErrDimensionMismatch || err == grid.ErrOutOfBounds {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err == grid.ErrReservationNotFound {
+		writeJSON(w, http.StatusNotFound, ReservationResponse{Success: false, Error: "reservation not found"})
+		return
+	}
+	if err == grid.ErrNotReservationOwner {
+		writeJSON(w, http.StatusForbidden, ReservationResponse{Success: false, Error: "reservation held by a different owner"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, ReservationResponse{Success: true, ExpiresAt: res.ExpiresAt})
+}
+
 func (api *API) RegisterRoutes(mux *http.ServeMux) {
+    mux.HandleFunc("/stats", api.HandleStats)
     mux.HandleFunc("/grids", api.HandleCreateGrid)
 
     mux.HandleFunc("/grids/", func(w http.ResponseWriter, r *http.Request) {
@@ -232,11 +550,26 @@ func (api *API) RegisterRoutes(mux *http.ServeMux) {
             return
         }
 
+        if len(parts) == 2 && parts[1] == "claim-batch" {
+            api.HandleClaimBatch(w, r, gridID)
+            return
+        }
+
         if len(parts) == 2 && parts[1] == "release" {
             api.HandleReleaseCell(w, r, gridID)
             return
         }
 
+        if len(parts) == 2 && parts[1] == "reserve" {
+            api.HandleReserveCell(w, r, gridID)
+            return
+        }
+
+        if len(parts) == 2 && parts[1] == "renew" {
+            api.HandleRenewReservation(w, r, gridID)
+            return
+        }
+
         if len(parts) == 2 && parts[1] == "ws" {
             api.HandleGridWS(w, r, gridID)
             return
@@ -246,6 +579,18 @@ func (api *API) RegisterRoutes(mux *http.ServeMux) {
     })
 }
 
+// HandleStats reports the local cache-hit metrics for the layered store,
+// if one is in use. Backends without a cache (e.g. a bare RedisStore)
+// report zeroed stats rather than an error, since "no caching layer" is
+// a valid deployment, not a failure.
+func (api *API) HandleStats(w http.ResponseWriter, r *http.Request) {
+    var stats utils.Stats
+    if ls, ok := api.Store.(*grid.LayeredStore); ok {
+        stats = ls.Stats()
+    }
+    writeJSON(w, http.StatusOK, stats)
+}
+
 func (api *API) HandleReleaseCell(w http.ResponseWriter, r *http.Request, gridID string) {
     if r.Method != http.MethodPost {
         writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
@@ -263,7 +608,12 @@ func (api *API) HandleReleaseCell(w http.ResponseWriter, r *http.Request, gridID
         return
     }
 
-    err := api.Store.ReleaseCell(gridID, req.Coord)
+    owner, ok := api.authenticate(w, r)
+    if !ok {
+        return
+    }
+
+    err := api.Store.ReleaseCell(gridID, req.Coord, owner)
     if err == grid.ErrGridNotFound {
         writeJSON(w, http.StatusNotFound, map[string]string{"error": "grid not found"})
         return
@@ -272,6 +622,10 @@ func (api *API) HandleReleaseCell(w http.ResponseWriter, r *http.Request, gridID
         writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
         return
     }
+    if err == grid.ErrNotCellOwner {
+        writeJSON(w, http.StatusForbidden, map[string]string{"error": "cell is owned by a different owner"})
+        return
+    }
     if err != nil {
         writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
         return
@@ -281,7 +635,8 @@ func (api *API) HandleReleaseCell(w http.ResponseWriter, r *http.Request, gridID
         Type:   "cell_released",
         GridID: gridID,
         Coord:  req.Coord,
-    })
+        Owner:  owner,
+    }, req.Coord)
 
     writeJSON(w, http.StatusOK, map[string]bool{"success": true})
 }
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ManadaHerath/realtime-grid-server/internal/grid"
+)
+
+// StartReservationSweeper listens for Redis keyspace expiry notifications
+// on reservation keys and publishes a cell_reservation_expired event so
+// every connected WebSocket client repaints the released tile. It relies
+// on the Redis server having `notify-keyspace-events` include `Ex`
+// (expired-key events); when it doesn't, reservations still expire via
+// PEXPIRE, clients just won't be proactively notified until they next
+// try to act on the cell. The sweeper runs until ctx is cancelled.
+func (api *API) StartReservationSweeper(ctx context.Context) {
+	if api.Redis == nil {
+		return
+	}
+
+	sub := api.Redis.PSubscribe(ctx, "__keyevent@0__:expired")
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			gridID, coord, ok := parseReservationKey(msg.Payload)
+			if !ok {
+				continue
+			}
+			api.publishCellEvent(ctx, CellUpdateEvent{
+				Type:   "cell_reservation_expired",
+				GridID: gridID,
+				Coord:  coord,
+			}, coord)
+		}
+	}
+}
+
+// parseReservationKey extracts the grid ID and coordinate from a
+// reservation key, in either RedisStore's flat "grid:<id>:resv:<coordKey>"
+// form or ChunkedRedisStore's hash-tagged
+// "{grid:<id>}:chunk:<chunkCoordKey>:resv:<coordKey>" form.
+func parseReservationKey(key string) (gridID string, coord []int, ok bool) {
+	if strings.HasPrefix(key, "{grid:") {
+		return parseChunkedReservationKey(key)
+	}
+
+	if !strings.HasPrefix(key, "grid:") {
+		return "", nil, false
+	}
+	rest := strings.TrimPrefix(key, "grid:")
+
+	parts := strings.SplitN(rest, ":resv:", 2)
+	if len(parts) != 2 {
+		return "", nil, false
+	}
+
+	coord, err := grid.ParseCoordKey(parts[1])
+	if err != nil {
+		return "", nil, false
+	}
+	return parts[0], coord, true
+}
+
+func parseChunkedReservationKey(key string) (gridID string, coord []int, ok bool) {
+	closeIdx := strings.Index(key, "}")
+	if closeIdx == -1 {
+		return "", nil, false
+	}
+	gridID = strings.TrimPrefix(key[:closeIdx], "{grid:")
+
+	idx := strings.LastIndex(key, ":resv:")
+	if idx == -1 || idx < closeIdx {
+		return "", nil, false
+	}
+
+	coord, err := grid.ParseCoordKey(key[idx+len(":resv:"):])
+	if err != nil {
+		return "", nil, false
+	}
+	return gridID, coord, true
+}
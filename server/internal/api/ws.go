@@ -2,10 +2,14 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 	"github.com/ManadaHerath/realtime-grid-server/internal/grid"
 )
 
@@ -13,6 +17,33 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
+// WebSocket connection tuning defaults, used when an API isn't given an
+// explicit WSConfig. A slow client that can't keep up with the send
+// buffer gets dropped rather than stalling the pub/sub reader for
+// everyone else sharing this process.
+const (
+	defaultWSReadDeadline  = 60 * time.Second
+	defaultWSWriteDeadline = 10 * time.Second
+	defaultWSPingInterval  = 30 * time.Second
+	defaultWSSendBuffer    = 64
+)
+
+// SnapshotEvent is sent once, right after "hello", with the requested
+// viewport's cells and the seq a client should expect the next live
+// event to be relative to. Clients that observe a gap between this seq
+// and a later event's seq know to request a fresh snapshot. Seq is
+// counted per grid, so on a chunked store a client subscribed to only
+// part of the grid also sees gaps for events published to chunks
+// outside its viewport.
+type SnapshotEvent struct {
+	Type   string          `json:"type"`
+	GridID string          `json:"gridId"`
+	Min    []int           `json:"min,omitempty"`
+	Max    []int           `json:"max,omitempty"`
+	Cells  []grid.CellView `json:"cells"`
+	Seq    int64           `json:"seq"`
+}
+
 func (api *API) HandleGridWS(w http.ResponseWriter, r *http.Request, gridID string) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -24,7 +55,8 @@ func (api *API) HandleGridWS(w http.ResponseWriter, r *http.Request, gridID stri
 		return
 	}
 
-	if _, err := api.Store.GetGrid(gridID); err != nil {
+	g, err := api.Store.GetGrid(gridID)
+	if err != nil {
 		if err == grid.ErrGridNotFound {
 			http.Error(w, "grid not found", http.StatusNotFound)
 			return
@@ -33,6 +65,12 @@ func (api *API) HandleGridWS(w http.ResponseWriter, r *http.Request, gridID stri
 		return
 	}
 
+	min, max, err := parseViewport(r, g.Dimensions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
@@ -42,12 +80,20 @@ func (api *API) HandleGridWS(w http.ResponseWriter, r *http.Request, gridID stri
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
-	channel := "grid:" + gridID + ":events"
-	sub := api.Redis.Subscribe(ctx, channel)
+	channels, err := api.viewportChannels(gridID, min, max)
+	if err != nil {
+		return
+	}
+	sub := api.Redis.Subscribe(ctx, channels...)
 	defer sub.Close()
 
-	ch := sub.Channel()
-	_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"hello","gridId":"`+gridID+`"}`))
+	send := make(chan []byte, api.WS.SendBuffer)
+
+	conn.SetReadDeadline(time.Now().Add(api.WS.ReadDeadline))
+	conn.SetPongHandler(func(string) error {
+		conn.SetReadDeadline(time.Now().Add(api.WS.ReadDeadline))
+		return nil
+	})
 
 	go func() {
 		defer cancel()
@@ -57,18 +103,168 @@ func (api *API) HandleGridWS(w http.ResponseWriter, r *http.Request, gridID stri
 			}
 		}
 	}()
+
+	go func() {
+		defer cancel()
+		ch := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case send <- []byte(msg.Payload):
+				default:
+					// The client can't keep up; drop it instead of
+					// blocking pub/sub delivery for other connections.
+					return
+				}
+			}
+		}
+	}()
+
+	if err := api.sendHello(conn, gridID); err != nil {
+		return
+	}
+	if err := api.sendSnapshot(ctx, conn, gridID, min, max); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(api.WS.PingInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
+			api.writeClose(conn, websocket.CloseTryAgainLater, "try again later")
 			return
-		case msg, ok := <-ch:
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(api.WS.WriteDeadline))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case payload, ok := <-send:
 			if !ok {
 				return
 			}
-			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg.Payload)); err != nil {
-				fmt.Println("ws write error:", err)
+			conn.SetWriteDeadline(time.Now().Add(api.WS.WriteDeadline))
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
 				return
 			}
 		}
 	}
 }
+
+func (api *API) sendHello(conn *websocket.Conn, gridID string) error {
+	conn.SetWriteDeadline(time.Now().Add(api.WS.WriteDeadline))
+	return conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"hello","gridId":"`+gridID+`"}`))
+}
+
+// sendSnapshot writes the cells within [min, max]. On a Chunked store
+// this only touches chunks intersecting the box. Other stores don't
+// support a bounded scan, so the whole grid is fetched and filtered down
+// to the box here instead — more Redis traffic than a real range query,
+// but the wire contract (Cells only ever covers [Min, Max]) still holds.
+func (api *API) sendSnapshot(ctx context.Context, conn *websocket.Conn, gridID string, min, max []int) error {
+	var cells []grid.CellView
+	if chunked, ok := grid.AsChunked(api.Store); ok {
+		if err := chunked.RangeCells(gridID, min, max, func(c grid.CellView) error {
+			cells = append(cells, c)
+			return nil
+		}); err != nil {
+			return err
+		}
+	} else {
+		all, err := api.Store.ListCells(gridID)
+		if err != nil {
+			return err
+		}
+		for _, c := range all {
+			if cellInRange(c.Coord, min, max) {
+				cells = append(cells, c)
+			}
+		}
+	}
+
+	seq, err := api.Redis.Get(ctx, seqKey(gridID)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	data, err := json.Marshal(SnapshotEvent{
+		Type:   "snapshot",
+		GridID: gridID,
+		Min:    min,
+		Max:    max,
+		Cells:  cells,
+		Seq:    seq,
+	})
+	if err != nil {
+		return err
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(api.WS.WriteDeadline))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// cellInRange reports whether coord falls within [min, max] inclusive on
+// every dimension.
+func cellInRange(coord, min, max []int) bool {
+	for i, c := range coord {
+		if c < min[i] || c > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseViewport reads the optional min/max query params bounding the
+// client's viewport, each a CoordKey-style "x:y:..." coordinate. With
+// neither given it defaults to the grid's full extent, so a plain
+// ".../ws" request behaves the same as before per-viewport support
+// existed.
+func parseViewport(r *http.Request, dimensions []int) (min, max []int, err error) {
+	minParam := r.URL.Query().Get("min")
+	maxParam := r.URL.Query().Get("max")
+	if minParam == "" && maxParam == "" {
+		min = make([]int, len(dimensions))
+		max = make([]int, len(dimensions))
+		for i, d := range dimensions {
+			max[i] = d - 1
+		}
+		return min, max, nil
+	}
+
+	min, err = grid.ParseCoordKey(minParam)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid min: %w", err)
+	}
+	max, err = grid.ParseCoordKey(maxParam)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid max: %w", err)
+	}
+	if len(min) != len(dimensions) || len(max) != len(dimensions) {
+		return nil, nil, grid.ErrDimensionMismatch
+	}
+	return min, max, nil
+}
+
+// viewportChannels returns the pub/sub channels a client watching
+// [min, max] should subscribe to. Chunked stores get one channel per
+// intersecting chunk so a viewport-scoped client isn't woken for writes
+// elsewhere in the grid; other stores don't shard events by chunk, so
+// they fall back to the single whole-grid channel.
+func (api *API) viewportChannels(gridID string, min, max []int) ([]string, error) {
+	if chunked, ok := grid.AsChunked(api.Store); ok {
+		return chunked.ChunkChannelsInRange(gridID, min, max)
+	}
+	return []string{eventsChannel(gridID)}, nil
+}
+
+func (api *API) writeClose(conn *websocket.Conn, code int, text string) {
+	conn.SetWriteDeadline(time.Now().Add(api.WS.WriteDeadline))
+	_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(code, text))
+}
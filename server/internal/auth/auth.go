@@ -0,0 +1,28 @@
+// Package auth extracts a caller identity from an inbound HTTP request so
+// the API layer can attribute claimed cells to their owner.
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrUnauthenticated is returned when a request carries no usable
+// credential for the configured Authenticator.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Authenticator resolves the caller identity for a request. The
+// returned string is stored as a cell's owner and compared against on
+// later writes.
+type Authenticator interface {
+	Authenticate(r *http.Request) (owner string, err error)
+}
+
+// NoopAuthenticator authenticates every request as the same, empty
+// owner. It exists so local dev and tests can run without configuring
+// real credentials.
+type NoopAuthenticator struct{}
+
+func (NoopAuthenticator) Authenticate(r *http.Request) (string, error) {
+	return "", nil
+}
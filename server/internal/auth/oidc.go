@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+)
+
+// ErrMissingUsernameClaim is returned when a verified ID token doesn't
+// carry the configured username claim.
+var ErrMissingUsernameClaim = errors.New("id token missing username claim")
+
+// OIDCAuthenticator verifies bearer ID tokens against an OIDC provider's
+// JWKS and reads the caller's identity out of a configurable claim, in
+// the style of Harbor's OIDC user-claim setting (default "sub" is
+// sometimes an opaque ID; deployments often prefer "email" or
+// "preferred_username").
+type OIDCAuthenticator struct {
+	verifier      *oidc.IDTokenVerifier
+	usernameClaim string
+}
+
+// NewOIDCAuthenticator builds an authenticator that fetches the
+// provider's JWKS from issuer's discovery document. usernameClaim
+// defaults to "sub" when empty.
+func NewOIDCAuthenticator(ctx context.Context, issuer, clientID, usernameClaim string) (*OIDCAuthenticator, error) {
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	if usernameClaim == "" {
+		usernameClaim = "sub"
+	}
+
+	return &OIDCAuthenticator{
+		verifier: provider.Verifier(&oidc.Config{ClientID: clientID}),
+		usernameClaim: usernameClaim,
+	}, nil
+}
+
+func (a *OIDCAuthenticator) Authenticate(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	rawToken := strings.TrimPrefix(header, "Bearer ")
+	if rawToken == "" || rawToken == header {
+		return "", ErrUnauthenticated
+	}
+
+	idToken, err := a.verifier.Verify(r.Context(), rawToken)
+	if err != nil {
+		return "", err
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", err
+	}
+
+	username, ok := claims[a.usernameClaim].(string)
+	if !ok || username == "" {
+		return "", ErrMissingUsernameClaim
+	}
+	return username, nil
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidToken is returned when the bearer token doesn't carry the
+// configured shared secret.
+var ErrInvalidToken = errors.New("invalid bearer token")
+
+// TokenAuthenticator authenticates requests carrying a shared-secret
+// bearer token of the form "Authorization: Bearer <secret>:<owner>".
+// Anyone who knows the secret can claim cells as any owner name they
+// put after the colon; it's meant for trusted internal clients or local
+// multi-user setups, not for isolating mutually distrusting users (use
+// OIDCAuthenticator for that).
+type TokenAuthenticator struct {
+	Secret string
+}
+
+func NewTokenAuthenticator(secret string) *TokenAuthenticator {
+	return &TokenAuthenticator{Secret: secret}
+}
+
+func (a *TokenAuthenticator) Authenticate(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(header, "Bearer ")
+	if token == "" || token == header {
+		return "", ErrUnauthenticated
+	}
+
+	secret, owner, found := strings.Cut(token, ":")
+	if !found || secret != a.Secret || owner == "" {
+		return "", ErrInvalidToken
+	}
+	return owner, nil
+}
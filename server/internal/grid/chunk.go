@@ -0,0 +1,92 @@
+package grid
+
+// Chunked is implemented by Store backends that shard a grid's cells
+// into fixed-size chunks (currently just ChunkedRedisStore), letting
+// callers work with a bounding box instead of the whole grid. AsChunked
+// is the usual way to obtain one.
+type Chunked interface {
+	ChunkChannelsForCoords(gridID string, coords [][]int) ([]string, error)
+	ChunkChannelsInRange(gridID string, min, max []int) ([]string, error)
+	ListChunk(gridID string, chunkCoord []int) ([]CellView, error)
+	RangeCells(gridID string, min, max []int, fn func(CellView) error) error
+}
+
+// AsChunked reports whether store is (or, for a *LayeredStore, wraps) a
+// Chunked backend, returning it if so. Callers use this to take the
+// per-chunk viewport path when it's available and fall back to the
+// whole-grid Store methods otherwise.
+func AsChunked(store Store) (Chunked, bool) {
+	if ls, ok := store.(*LayeredStore); ok {
+		store = ls.Backing()
+	}
+	c, ok := store.(Chunked)
+	return c, ok
+}
+
+// defaultChunkDim is the per-dimension chunk size used when a
+// ChunkedRedisStore isn't given an explicit one (e.g. the 64x64 tiling
+// suggested for 2D grids).
+const defaultChunkDim = 64
+
+// ChunkCoord maps a cell coordinate to the coordinate of the chunk that
+// contains it, given a per-dimension chunk size.
+func ChunkCoord(coord []int, chunkSize []int) []int {
+	chunkCoord := make([]int, len(coord))
+	for i, c := range coord {
+		chunkCoord[i] = floorDiv(c, chunkSize[i])
+	}
+	return chunkCoord
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// inRange reports whether coord falls within [min, max] inclusive on
+// every dimension.
+func inRange(coord, min, max []int) bool {
+	for i, c := range coord {
+		if c < min[i] || c > max[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// walkChunkCoords calls visit once for every chunk coordinate in the
+// inclusive range [chunkMin, chunkMax], in row-major order. It stops and
+// returns visit's error as soon as one occurs.
+func walkChunkCoords(chunkMin, chunkMax []int, visit func([]int) error) error {
+	dims := len(chunkMin)
+	if dims == 0 {
+		return nil
+	}
+
+	cur := make([]int, dims)
+	copy(cur, chunkMin)
+
+	for {
+		coord := make([]int, dims)
+		copy(coord, cur)
+		if err := visit(coord); err != nil {
+			return err
+		}
+
+		i := dims - 1
+		for i >= 0 {
+			cur[i]++
+			if cur[i] <= chunkMax[i] {
+				break
+			}
+			cur[i] = chunkMin[i]
+			i--
+		}
+		if i < 0 {
+			return nil
+		}
+	}
+}
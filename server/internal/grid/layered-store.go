@@ -0,0 +1,172 @@
+package grid
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/ManadaHerath/realtime-grid-server/utils"
+)
+
+// LayeredStore wraps a backing Store (normally a *RedisStore) with a
+// local in-process LRU that serves GetGrid and ListCells without a
+// round trip to Redis on the common "many WebSocket clients loading
+// the same grid" path. Writes always go straight to the backing store;
+// on success they publish an invalidation message so every other
+// server instance evicts its own copy of the affected grid.
+type LayeredStore struct {
+	backing Store
+	rdb     *redis.Client
+	cache   *utils.LRU
+}
+
+const invalidateChannelSuffix = ":invalidate"
+
+func invalidateChannel(gridID string) string {
+	return "grid:" + gridID + ":invalidate"
+}
+
+func gridCacheKey(gridID string) string {
+	return "grid:" + gridID
+}
+
+func cellsCacheKey(gridID string) string {
+	return "cells:" + gridID
+}
+
+// NewLayeredStore wraps backing with a local LRU cache of the given size
+// and per-entry TTL, and subscribes to invalidation events on ctx so
+// entries evicted by writes on other instances are dropped locally too.
+// The subscription goroutine exits when ctx is cancelled.
+func NewLayeredStore(ctx context.Context, backing Store, rdb *redis.Client, size int, ttl time.Duration) *LayeredStore {
+	ls := &LayeredStore{
+		backing: backing,
+		rdb:     rdb,
+		cache:   utils.NewLRU(size, ttl),
+	}
+	if rdb != nil {
+		go ls.subscribeInvalidations(ctx)
+	}
+	return ls
+}
+
+func (s *LayeredStore) subscribeInvalidations(ctx context.Context) {
+	sub := s.rdb.PSubscribe(ctx, "grid:*"+invalidateChannelSuffix)
+	defer sub.Close()
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			gridID := msg.Payload
+			s.evictGrid(gridID)
+		}
+	}
+}
+
+func (s *LayeredStore) evictGrid(gridID string) {
+	s.cache.Delete(gridCacheKey(gridID))
+	s.cache.Delete(cellsCacheKey(gridID))
+}
+
+func (s *LayeredStore) publishInvalidation(gridID string) {
+	if s.rdb == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.rdb.Publish(ctx, invalidateChannel(gridID), gridID)
+}
+
+// Stats returns cache-hit metrics for the local LRU.
+func (s *LayeredStore) Stats() utils.Stats {
+	return s.cache.Stats()
+}
+
+// Backing returns the store LayeredStore wraps, so callers can
+// type-assert for capabilities (like chunked range queries) that
+// LayeredStore itself doesn't expose.
+func (s *LayeredStore) Backing() Store {
+	return s.backing
+}
+
+func (s *LayeredStore) CreateGrid(dimensions []int, defaultVal interface{}) (*Grid, error) {
+	return s.backing.CreateGrid(dimensions, defaultVal)
+}
+
+func (s *LayeredStore) GetGrid(id string) (*Grid, error) {
+	if v, ok := s.cache.Get(gridCacheKey(id)); ok {
+		return v.(*Grid), nil
+	}
+
+	g, err := s.backing.GetGrid(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(gridCacheKey(id), g)
+	return g, nil
+}
+
+func (s *LayeredStore) SetCell(gridID string, coord []int, value interface{}, owner string) error {
+	if err := s.backing.SetCell(gridID, coord, value, owner); err != nil {
+		return err
+	}
+	s.evictGrid(gridID)
+	s.publishInvalidation(gridID)
+	return nil
+}
+
+func (s *LayeredStore) SetCells(gridID string, ops []CellOp, owner string) ([]CellResult, error) {
+	results, err := s.backing.SetCells(gridID, ops, owner)
+	if err == nil {
+		s.evictGrid(gridID)
+		s.publishInvalidation(gridID)
+	}
+	return results, err
+}
+
+func (s *LayeredStore) ListCells(gridID string) ([]CellView, error) {
+	if v, ok := s.cache.Get(cellsCacheKey(gridID)); ok {
+		return v.([]CellView), nil
+	}
+
+	cells, err := s.backing.ListCells(gridID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.Set(cellsCacheKey(gridID), cells)
+	return cells, nil
+}
+
+func (s *LayeredStore) ReleaseCell(gridID string, coord []int, owner string) error {
+	if err := s.backing.ReleaseCell(gridID, coord, owner); err != nil {
+		return err
+	}
+	s.evictGrid(gridID)
+	s.publishInvalidation(gridID)
+	return nil
+}
+
+func (s *LayeredStore) ReserveCell(gridID string, coord []int, owner string, value interface{}, ttl time.Duration) (*Reservation, error) {
+	return s.backing.ReserveCell(gridID, coord, owner, value, ttl)
+}
+
+func (s *LayeredStore) RenewReservation(gridID string, coord []int, owner string, ttl time.Duration) (*Reservation, error) {
+	return s.backing.RenewReservation(gridID, coord, owner, ttl)
+}
+
+func (s *LayeredStore) FinalizeCell(gridID string, coord []int, owner string, value interface{}) error {
+	if err := s.backing.FinalizeCell(gridID, coord, owner, value); err != nil {
+		return err
+	}
+	s.evictGrid(gridID)
+	s.publishInvalidation(gridID)
+	return nil
+}
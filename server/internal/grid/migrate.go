@@ -0,0 +1,65 @@
+package grid
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MigrateFlatToChunked copies a grid's cells out of the single-hash
+// layout used by RedisStore and into the chunked layout used by
+// ChunkedRedisStore, then deletes the flat hash. It does not touch
+// reservations: any in-flight reservation is expected to have been
+// drained (or simply expire) before a grid is migrated, since the two
+// stores key reservations differently.
+//
+// This is meant to be run offline against a grid that has stopped
+// taking writes; it does not take a lock and a concurrent SetCell
+// against the flat store could be lost.
+func MigrateFlatToChunked(ctx context.Context, client *redis.Client, gridID string, chunkSize []int) error {
+	metaVals, err := client.HGetAll(ctx, metaKey(gridID)).Result()
+	if err != nil {
+		return err
+	}
+	if len(metaVals) == 0 {
+		return ErrGridNotFound
+	}
+	if err := client.HSet(ctx, chunkedMetaKey(gridID), metaVals).Err(); err != nil {
+		return err
+	}
+
+	dst := &ChunkedRedisStore{client: client, chunkSize: chunkSize}
+	g, err := dst.GetGrid(gridID)
+	if err != nil {
+		return err
+	}
+	dims := dst.chunkSizeForDims(len(g.Dimensions))
+
+	entries, err := client.HGetAll(ctx, cellsKey(gridID)).Result()
+	if err != nil {
+		return err
+	}
+
+	for field, raw := range entries {
+		coord, err := ParseCoordKey(field)
+		if err != nil {
+			continue
+		}
+
+		chunkCoord := ChunkCoord(coord, dims)
+		if err := client.HSet(ctx, chunkedChunkKey(gridID, chunkCoord), field, raw).Err(); err != nil {
+			return err
+		}
+		if err := client.SAdd(ctx, chunkedChunksSetKey(gridID), CoordKey(chunkCoord)).Err(); err != nil {
+			return err
+		}
+	}
+
+	if len(entries) > 0 {
+		if err := client.Del(ctx, cellsKey(gridID)).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
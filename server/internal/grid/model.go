@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Grid struct {
@@ -17,6 +18,30 @@ type Grid struct {
 type CellView struct {
 	Coord []int      `json:"coord"`
 	Value interface{} `json:"value"`
+	Owner string     `json:"owner,omitempty"`
+}
+
+// Reservation is a time-limited, exclusive hold on a single cell that
+// lets a caller stage a value before committing it with FinalizeCell.
+type Reservation struct {
+	Owner          string      `json:"owner"`
+	ExpiresAt      time.Time   `json:"expiresAt"`
+	TentativeValue interface{} `json:"tentativeValue,omitempty"`
+}
+
+// CellOp is one coordinate/value pair in a SetCells batch.
+type CellOp struct {
+	Coord []int       `json:"coord"`
+	Value interface{} `json:"value"`
+}
+
+// CellResult reports the outcome of a single coordinate within a
+// SetCells batch. Success is false for every cell when the batch as a
+// whole is rejected, even ones that would individually have succeeded.
+type CellResult struct {
+	Coord   []int  `json:"coord"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
 }
 
 func GenerateID() string {
@@ -33,6 +58,23 @@ func CoordKey(coord []int) string {
 	return strings.Join(parts, ":")
 }
 
+// duplicateCoordKey returns the CoordKey of the first coordinate that
+// appears more than once in ops, or "" if every coordinate is unique.
+// SetCells implementations reject a batch with one up front rather than
+// let it pass the per-cell claim check twice and silently clobber
+// itself in the write phase.
+func duplicateCoordKey(ops []CellOp) string {
+	seen := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		key := CoordKey(op.Coord)
+		if seen[key] {
+			return key
+		}
+		seen[key] = true
+	}
+	return ""
+}
+
 func ParseCoordKey(key string) ([]int, error) {
 	if key == "" {
 		return nil, nil
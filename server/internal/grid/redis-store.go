@@ -15,6 +15,214 @@ type RedisStore struct {
 	client *redis.Client
 }
 
+// cellRecord is the JSON blob stored in a grid's cells hash, one per
+// coordinate. It carries the committed value alongside who claimed it
+// and when, so ListCells can render attribution.
+type cellRecord struct {
+	Value     interface{} `json:"value"`
+	Owner     string      `json:"owner,omitempty"`
+	ClaimedAt time.Time   `json:"claimedAt,omitempty"`
+}
+
+const (
+	cellSetOK = iota
+	cellSetAlreadySet
+	cellSetReserved
+)
+
+// setCellScript commits a value into the cells hash unless the field is
+// already set or an unexpired reservation is held there. KEYS[1] is the
+// cells hash, KEYS[2] is the cell's reservation key. ARGV[1] is the
+// coord field, ARGV[2] is the JSON-encoded value.
+var setCellScript = redis.NewScript(`
+local cKey = KEYS[1]
+local rKey = KEYS[2]
+local field = ARGV[1]
+local val = ARGV[2]
+
+local existing = redis.call("HGET", cKey, field)
+if existing ~= false and existing ~= nil then
+  return 1
+end
+
+if redis.call("EXISTS", rKey) == 1 then
+  return 2
+end
+
+redis.call("HSET", cKey, field, val)
+return 0
+`)
+
+const (
+	reserveOK = iota
+	reserveAlreadySet
+	reserveHeldByOther
+)
+
+// reserveCellScript creates or refreshes a reservation for a cell,
+// refusing to do so if the cell already has a committed value or an
+// unexpired reservation held by a different owner. KEYS[1] is the cells
+// hash, KEYS[2] is the reservation key. ARGV[1] is the coord field,
+// ARGV[2] is the owner, ARGV[3] is the TTL in milliseconds, ARGV[4] is
+// the RFC3339 expiry to store for observability, ARGV[5] is the
+// JSON-encoded tentative value.
+var reserveCellScript = redis.NewScript(`
+local cKey = KEYS[1]
+local rKey = KEYS[2]
+local field = ARGV[1]
+local owner = ARGV[2]
+local ttlMs = ARGV[3]
+local expiresAt = ARGV[4]
+local val = ARGV[5]
+
+if redis.call("HEXISTS", cKey, field) == 1 then
+  return 1
+end
+
+local existingOwner = redis.call("HGET", rKey, "owner")
+if existingOwner ~= false and existingOwner ~= owner then
+  return 2
+end
+
+redis.call("HSET", rKey, "owner", owner, "expiresAt", expiresAt, "value", val)
+redis.call("PEXPIRE", rKey, ttlMs)
+return 0
+`)
+
+const (
+	reservationNotFound = iota
+	reservationNotOwner
+	reservationRenewed
+)
+
+// renewReservationScript extends an existing reservation's TTL if it is
+// still held by owner. KEYS[1] is the reservation key. ARGV[1] is the
+// owner, ARGV[2] is the TTL in milliseconds, ARGV[3] is the RFC3339
+// expiry to store for observability.
+var renewReservationScript = redis.NewScript(`
+local rKey = KEYS[1]
+local owner = ARGV[1]
+local ttlMs = ARGV[2]
+local expiresAt = ARGV[3]
+
+local existingOwner = redis.call("HGET", rKey, "owner")
+if existingOwner == false then
+  return 0
+end
+if existingOwner ~= owner then
+  return 1
+end
+
+redis.call("HSET", rKey, "expiresAt", expiresAt)
+redis.call("PEXPIRE", rKey, ttlMs)
+return 2
+`)
+
+const (
+	finalizeOK = iota
+	finalizeNotFound
+	finalizeNotOwner
+	finalizeAlreadySet
+)
+
+// finalizeCellScript commits a reserved cell's value and clears the
+// reservation, atomically. KEYS[1] is the cells hash, KEYS[2] is the
+// reservation key. ARGV[1] is the coord field, ARGV[2] is the owner,
+// ARGV[3] is the JSON-encoded value.
+var finalizeCellScript = redis.NewScript(`
+local cKey = KEYS[1]
+local rKey = KEYS[2]
+local field = ARGV[1]
+local owner = ARGV[2]
+local val = ARGV[3]
+
+local existingOwner = redis.call("HGET", rKey, "owner")
+if existingOwner == false then
+  return 1
+end
+if existingOwner ~= owner then
+  return 2
+end
+if redis.call("HEXISTS", cKey, field) == 1 then
+  return 3
+end
+
+redis.call("HSET", cKey, field, val)
+redis.call("DEL", rKey)
+return 0
+`)
+
+const (
+	batchCellOK = iota
+	batchCellAlreadySet
+	batchCellReserved
+)
+
+const (
+	releaseOK = iota
+	releaseForbidden
+)
+
+// releaseCellScript deletes a committed cell unless it is owned by
+// someone else. KEYS[1] is the cells hash. ARGV[1] is the coord field,
+// ARGV[2] is the caller's owner (may be empty in anonymous mode).
+var releaseCellScript = redis.NewScript(`
+local cKey = KEYS[1]
+local field = ARGV[1]
+local owner = ARGV[2]
+
+local existing = redis.call("HGET", cKey, field)
+if existing == false then
+  return 0
+end
+
+local rec = cjson.decode(existing)
+if rec.owner ~= nil and rec.owner ~= "" and rec.owner ~= owner then
+  return 1
+end
+
+redis.call("HDEL", cKey, field)
+return 0
+`)
+
+// setCellsScript claims every requested cell or none, so a caller can
+// place a multi-cell shape without another client racing part of it.
+// KEYS[1] is the cells hash, KEYS[2..n+1] are the per-cell reservation
+// keys. ARGV[1] is n, ARGV[2..n+1] are the coord fields, ARGV[n+2..2n+1]
+// are the JSON-encoded values. Returns an n-length array of per-cell
+// status codes; the writes only happen if every status is 0.
+var setCellsScript = redis.NewScript(`
+local cKey = KEYS[1]
+local n = tonumber(ARGV[1])
+
+local statuses = {}
+local allOk = true
+for i = 1, n do
+  local field = ARGV[1 + i]
+  local rKey = KEYS[1 + i]
+  local existing = redis.call("HGET", cKey, field)
+  if existing ~= false and existing ~= nil then
+    statuses[i] = 1
+    allOk = false
+  elseif redis.call("EXISTS", rKey) == 1 then
+    statuses[i] = 2
+    allOk = false
+  else
+    statuses[i] = 0
+  end
+end
+
+if allOk then
+  for i = 1, n do
+    local field = ARGV[1 + i]
+    local val = ARGV[1 + n + i]
+    redis.call("HSET", cKey, field, val)
+  end
+end
+
+return statuses
+`)
+
 func NewRedisStore(addr string, password string, db int) Store {
 	rdb := redis.NewClient(&redis.Options{
 		Addr:     addr,
@@ -32,6 +240,10 @@ func cellsKey(gridID string) string {
 	return "grid:" + gridID + ":cells"
 }
 
+func reservationKey(gridID, coordKey string) string {
+	return "grid:" + gridID + ":resv:" + coordKey
+}
+
 func (s *RedisStore) CreateGrid(dimensions []int, defaultVal interface{}) (*Grid, error) {
 	if len(dimensions) == 0 {
 		return nil, errors.New("dimensions required")
@@ -123,7 +335,7 @@ func (s *RedisStore) GetGrid(id string) (*Grid, error) {
 	}, nil
 }
 
-func (s *RedisStore) SetCell(gridID string, coord []int, value interface{}) error {
+func (s *RedisStore) SetCell(gridID string, coord []int, value interface{}, owner string) error {
 	g, err := s.GetGrid(gridID)
 	if err != nil {
 		return err
@@ -142,35 +354,106 @@ func (s *RedisStore) SetCell(gridID string, coord []int, value interface{}) erro
 
 	key := CoordKey(coord)
 	cKey := cellsKey(gridID)
+	rKey := reservationKey(gridID, key)
 
-	valBytes, err := json.Marshal(value)
+	valBytes, err := json.Marshal(cellRecord{Value: value, Owner: owner, ClaimedAt: time.Now()})
 	if err != nil {
 		return err
 	}
 	valStr := string(valBytes)
-	script := redis.NewScript(`
-local cKey = KEYS[1]
-local field = ARGV[1]
-local val = ARGV[2]
-
-local existing = redis.call("HGET", cKey, field)
-if existing ~= false and existing ~= nil then
-  return 0
-end
 
-redis.call("HSET", cKey, field, val)
-return 1
-`)
-
-	res, err := script.Run(ctx, s.client, []string{cKey}, key, valStr).Int()
+	res, err := setCellScript.Run(ctx, s.client, []string{cKey, rKey}, key, valStr).Int()
 	if err != nil {
 		return err
 	}
-	if res == 0 {
+	switch res {
+	case cellSetOK:
+		return nil
+	case cellSetAlreadySet:
 		return ErrCellAlreadySet
+	case cellSetReserved:
+		return ErrCellReserved
+	default:
+		return fmt.Errorf("unexpected result from setCellScript: %d", res)
+	}
+}
+
+func (s *RedisStore) SetCells(gridID string, ops []CellOp, owner string) ([]CellResult, error) {
+	g, err := s.GetGrid(gridID)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(ops)
+	fields := make([]string, n)
+	for i, op := range ops {
+		if len(op.Coord) != len(g.Dimensions) {
+			return nil, ErrDimensionMismatch
+		}
+		for j, c := range op.Coord {
+			if c < 0 || c >= g.Dimensions[j] {
+				return nil, ErrOutOfBounds
+			}
+		}
+		fields[i] = CoordKey(op.Coord)
+	}
+	if duplicateCoordKey(ops) != "" {
+		return nil, ErrDuplicateCoord
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys := make([]string, 0, n+1)
+	keys = append(keys, cellsKey(gridID))
+	for _, field := range fields {
+		keys = append(keys, reservationKey(gridID, field))
+	}
+
+	argv := make([]interface{}, 0, 1+2*n)
+	argv = append(argv, n)
+	for _, field := range fields {
+		argv = append(argv, field)
+	}
+	claimedAt := time.Now()
+	for _, op := range ops {
+		valBytes, err := json.Marshal(cellRecord{Value: op.Value, Owner: owner, ClaimedAt: claimedAt})
+		if err != nil {
+			return nil, err
+		}
+		argv = append(argv, string(valBytes))
 	}
 
-	return nil
+	statuses, err := setCellsScript.Run(ctx, s.client, keys, argv...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CellResult, n)
+	allOk := true
+	for i, status := range statuses {
+		results[i] = CellResult{Coord: ops[i].Coord}
+		switch status {
+		case batchCellOK:
+			results[i].Success = true
+		case batchCellAlreadySet:
+			results[i].Error = ErrCellAlreadySet.Error()
+			allOk = false
+		case batchCellReserved:
+			results[i].Error = ErrCellReserved.Error()
+			allOk = false
+		default:
+			return nil, fmt.Errorf("unexpected status from setCellsScript: %d", status)
+		}
+	}
+
+	if !allOk {
+		for i := range results {
+			results[i].Success = false
+		}
+		return results, ErrBatchClaimFailed
+	}
+	return results, nil
 }
 
 func (s *RedisStore) ListCells(gridID string) ([]CellView, error) {
@@ -194,20 +477,21 @@ func (s *RedisStore) ListCells(gridID string) ([]CellView, error) {
 			continue
 		}
 
-		var val interface{}
-		if err := json.Unmarshal([]byte(v), &val); err != nil {
-			val = v
+		var rec cellRecord
+		if err := json.Unmarshal([]byte(v), &rec); err != nil {
+			rec.Value = v
 		}
 
 		cells = append(cells, CellView{
 			Coord: coord,
-			Value: val,
+			Value: rec.Value,
+			Owner: rec.Owner,
 		})
 	}
 	return cells, nil
 }
 
-func (s *RedisStore) ReleaseCell(gridID string, coord []int) error {
+func (s *RedisStore) ReleaseCell(gridID string, coord []int, owner string) error {
     g, err := s.GetGrid(gridID)
     if err != nil {
         return err
@@ -227,9 +511,145 @@ func (s *RedisStore) ReleaseCell(gridID string, coord []int) error {
     key := CoordKey(coord)
     cKey := cellsKey(gridID)
 
-    if err := s.client.HDel(ctx, cKey, key).Err(); err != nil {
+    res, err := releaseCellScript.Run(ctx, s.client, []string{cKey}, key, owner).Int()
+    if err != nil {
+        return err
+    }
+    if res == releaseForbidden {
+        return ErrNotCellOwner
+    }
+
+    if err := s.client.Del(ctx, reservationKey(gridID, key)).Err(); err != nil {
         return err
     }
 
     return nil
 }
+
+func (s *RedisStore) ReserveCell(gridID string, coord []int, owner string, value interface{}, ttl time.Duration) (*Reservation, error) {
+	g, err := s.GetGrid(gridID)
+	if err != nil {
+		return nil, err
+	}
+	if len(coord) != len(g.Dimensions) {
+		return nil, ErrDimensionMismatch
+	}
+	for i, c := range coord {
+		if c < 0 || c >= g.Dimensions[i] {
+			return nil, ErrOutOfBounds
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := CoordKey(coord)
+	expiresAt := time.Now().Add(ttl)
+
+	valBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := reserveCellScript.Run(ctx, s.client,
+		[]string{cellsKey(gridID), reservationKey(gridID, key)},
+		key, owner, ttl.Milliseconds(), expiresAt.Format(time.RFC3339), string(valBytes),
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	switch res {
+	case reserveOK:
+		return &Reservation{Owner: owner, ExpiresAt: expiresAt, TentativeValue: value}, nil
+	case reserveAlreadySet:
+		return nil, ErrCellAlreadySet
+	case reserveHeldByOther:
+		return nil, ErrCellReserved
+	default:
+		return nil, fmt.Errorf("unexpected result from reserveCellScript: %d", res)
+	}
+}
+
+func (s *RedisStore) RenewReservation(gridID string, coord []int, owner string, ttl time.Duration) (*Reservation, error) {
+	g, err := s.GetGrid(gridID)
+	if err != nil {
+		return nil, err
+	}
+	if len(coord) != len(g.Dimensions) {
+		return nil, ErrDimensionMismatch
+	}
+	for i, c := range coord {
+		if c < 0 || c >= g.Dimensions[i] {
+			return nil, ErrOutOfBounds
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := CoordKey(coord)
+	expiresAt := time.Now().Add(ttl)
+
+	res, err := renewReservationScript.Run(ctx, s.client,
+		[]string{reservationKey(gridID, key)},
+		owner, ttl.Milliseconds(), expiresAt.Format(time.RFC3339),
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	switch res {
+	case reservationRenewed:
+		return &Reservation{Owner: owner, ExpiresAt: expiresAt}, nil
+	case reservationNotFound:
+		return nil, ErrReservationNotFound
+	case reservationNotOwner:
+		return nil, ErrNotReservationOwner
+	default:
+		return nil, fmt.Errorf("unexpected result from renewReservationScript: %d", res)
+	}
+}
+
+func (s *RedisStore) FinalizeCell(gridID string, coord []int, owner string, value interface{}) error {
+	g, err := s.GetGrid(gridID)
+	if err != nil {
+		return err
+	}
+	if len(coord) != len(g.Dimensions) {
+		return ErrDimensionMismatch
+	}
+	for i, c := range coord {
+		if c < 0 || c >= g.Dimensions[i] {
+			return ErrOutOfBounds
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key := CoordKey(coord)
+
+	valBytes, err := json.Marshal(cellRecord{Value: value, Owner: owner, ClaimedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	res, err := finalizeCellScript.Run(ctx, s.client,
+		[]string{cellsKey(gridID), reservationKey(gridID, key)},
+		key, owner, string(valBytes),
+	).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case finalizeOK:
+		return nil
+	case finalizeNotFound:
+		return ErrReservationNotFound
+	case finalizeNotOwner:
+		return ErrNotReservationOwner
+	case finalizeAlreadySet:
+		return ErrCellAlreadySet
+	default:
+		return fmt.Errorf("unexpected result from finalizeCellScript: %d", res)
+	}
+}
@@ -3,34 +3,222 @@ package grid
 import (
 	"errors"
 	"sync"
+	"time"
 )
 
 var (
-	ErrGridNotFound      = errors.New("grid not found")
-	ErrDimensionMismatch = errors.New("coord dimension mismatch")
-	ErrOutOfBounds       = errors.New("coord out of bounds")
-	ErrCellAlreadySet    = errors.New("cell already set") 
+	ErrGridNotFound        = errors.New("grid not found")
+	ErrDimensionMismatch   = errors.New("coord dimension mismatch")
+	ErrOutOfBounds         = errors.New("coord out of bounds")
+	ErrCellAlreadySet      = errors.New("cell already set")
+	ErrCellReserved        = errors.New("cell has an active reservation")
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrNotReservationOwner = errors.New("reservation held by a different owner")
+	ErrBatchClaimFailed    = errors.New("one or more cells in the batch could not be claimed")
+	ErrNotCellOwner        = errors.New("cell is owned by a different owner")
+	ErrDuplicateCoord      = errors.New("batch contains a duplicate coordinate")
 )
 type Store interface {
     CreateGrid(dimensions []int, defaultVal interface{}) (*Grid, error)
     GetGrid(id string) (*Grid, error)
-    SetCell(gridID string, coord []int, value interface{}) error
+    SetCell(gridID string, coord []int, value interface{}, owner string) error
     ListCells(gridID string) ([]CellView, error)
-    ReleaseCell(gridID string, coord []int) error 
+    ReleaseCell(gridID string, coord []int, owner string) error
+
+    SetCells(gridID string, ops []CellOp, owner string) ([]CellResult, error)
+
+    ReserveCell(gridID string, coord []int, owner string, value interface{}, ttl time.Duration) (*Reservation, error)
+    RenewReservation(gridID string, coord []int, owner string, ttl time.Duration) (*Reservation, error)
+    FinalizeCell(gridID string, coord []int, owner string, value interface{}) error
 }
 
 type MemStore struct {
-	mu    sync.RWMutex
-	grids map[string]*Grid
+	mu           sync.RWMutex
+	grids        map[string]*Grid
+	reservations map[string]map[string]*Reservation
+	owners       map[string]map[string]string
 }
 
 func NewMemStore() Store {
 	return &MemStore{
-		grids: make(map[string]*Grid),
+		grids:        make(map[string]*Grid),
+		reservations: make(map[string]map[string]*Reservation),
+		owners:       make(map[string]map[string]string),
+	}
+}
+
+func (s *MemStore) setOwnerLocked(gridID, key, owner string) {
+	if owner == "" {
+		return
+	}
+	if _, ok := s.owners[gridID]; !ok {
+		s.owners[gridID] = make(map[string]string)
+	}
+	s.owners[gridID][key] = owner
+}
+
+// reservationLocked returns the live reservation for key, if any,
+// dropping it in place if it has expired. Callers must hold s.mu.
+func (s *MemStore) reservationLocked(gridID, key string) *Reservation {
+	byCoord, ok := s.reservations[gridID]
+	if !ok {
+		return nil
+	}
+	r, ok := byCoord[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(r.ExpiresAt) {
+		delete(byCoord, key)
+		return nil
+	}
+	return r
+}
+
+func (s *MemStore) checkCoord(g *Grid, coord []int) error {
+	if len(coord) != len(g.Dimensions) {
+		return ErrDimensionMismatch
+	}
+	for i, c := range coord {
+		if c < 0 || c >= g.Dimensions[i] {
+			return ErrOutOfBounds
+		}
+	}
+	return nil
+}
+
+func (s *MemStore) SetCells(gridID string, ops []CellOp, owner string) ([]CellResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grids[gridID]
+	if !ok {
+		return nil, ErrGridNotFound
+	}
+	if duplicateCoordKey(ops) != "" {
+		return nil, ErrDuplicateCoord
+	}
+
+	results := make([]CellResult, len(ops))
+	allOk := true
+	for i, op := range ops {
+		if err := s.checkCoord(g, op.Coord); err != nil {
+			return nil, err
+		}
+
+		key := CoordKey(op.Coord)
+		if _, exists := g.Cells[key]; exists {
+			results[i] = CellResult{Coord: op.Coord, Error: ErrCellAlreadySet.Error()}
+			allOk = false
+			continue
+		}
+		if r := s.reservationLocked(gridID, key); r != nil {
+			results[i] = CellResult{Coord: op.Coord, Error: ErrCellReserved.Error()}
+			allOk = false
+			continue
+		}
+		results[i] = CellResult{Coord: op.Coord, Success: true}
+	}
+
+	if !allOk {
+		for i := range results {
+			results[i].Success = false
+		}
+		return results, ErrBatchClaimFailed
+	}
+
+	for _, op := range ops {
+		key := CoordKey(op.Coord)
+		g.Cells[key] = op.Value
+		s.setOwnerLocked(gridID, key, owner)
+	}
+	return results, nil
+}
+
+func (s *MemStore) ReserveCell(gridID string, coord []int, owner string, value interface{}, ttl time.Duration) (*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grids[gridID]
+	if !ok {
+		return nil, ErrGridNotFound
+	}
+	if err := s.checkCoord(g, coord); err != nil {
+		return nil, err
+	}
+
+	key := CoordKey(coord)
+	if _, exists := g.Cells[key]; exists {
+		return nil, ErrCellAlreadySet
+	}
+	if existing := s.reservationLocked(gridID, key); existing != nil && existing.Owner != owner {
+		return nil, ErrCellReserved
+	}
+
+	r := &Reservation{Owner: owner, ExpiresAt: time.Now().Add(ttl), TentativeValue: value}
+	if _, ok := s.reservations[gridID]; !ok {
+		s.reservations[gridID] = make(map[string]*Reservation)
+	}
+	s.reservations[gridID][key] = r
+	return r, nil
+}
+
+func (s *MemStore) RenewReservation(gridID string, coord []int, owner string, ttl time.Duration) (*Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grids[gridID]
+	if !ok {
+		return nil, ErrGridNotFound
+	}
+	if err := s.checkCoord(g, coord); err != nil {
+		return nil, err
+	}
+
+	key := CoordKey(coord)
+	r := s.reservationLocked(gridID, key)
+	if r == nil {
+		return nil, ErrReservationNotFound
+	}
+	if r.Owner != owner {
+		return nil, ErrNotReservationOwner
 	}
+
+	r.ExpiresAt = time.Now().Add(ttl)
+	return r, nil
+}
+
+func (s *MemStore) FinalizeCell(gridID string, coord []int, owner string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.grids[gridID]
+	if !ok {
+		return ErrGridNotFound
+	}
+	if err := s.checkCoord(g, coord); err != nil {
+		return err
+	}
+
+	key := CoordKey(coord)
+	r := s.reservationLocked(gridID, key)
+	if r == nil {
+		return ErrReservationNotFound
+	}
+	if r.Owner != owner {
+		return ErrNotReservationOwner
+	}
+	if _, exists := g.Cells[key]; exists {
+		return ErrCellAlreadySet
+	}
+
+	g.Cells[key] = value
+	s.setOwnerLocked(gridID, key, owner)
+	delete(s.reservations[gridID], key)
+	return nil
 }
 
-func (s *MemStore) ReleaseCell(gridID string, coord []int) error {
+func (s *MemStore) ReleaseCell(gridID string, coord []int, owner string) error {
     s.mu.Lock()
     defer s.mu.Unlock()
 
@@ -50,7 +238,17 @@ func (s *MemStore) ReleaseCell(gridID string, coord []int) error {
     }
 
     key := CoordKey(coord)
+    if existing, ok := s.owners[gridID][key]; ok && existing != owner {
+        return ErrNotCellOwner
+    }
+
     delete(g.Cells, key)
+    if byOwner, ok := s.owners[gridID]; ok {
+        delete(byOwner, key)
+    }
+    if byCoord, ok := s.reservations[gridID]; ok {
+        delete(byCoord, key)
+    }
     return nil
 }
 
@@ -88,7 +286,7 @@ func (s *MemStore) GetGrid(id string) (*Grid, error) {
 	return g, nil
 }
 
-func (s *MemStore) SetCell(gridID string, coord []int, value interface{}) error {
+func (s *MemStore) SetCell(gridID string, coord []int, value interface{}, owner string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -113,8 +311,12 @@ func (s *MemStore) SetCell(gridID string, coord []int, value interface{}) error
 	if _, exists := g.Cells[key]; exists {
 		return ErrCellAlreadySet
 	}
+	if r := s.reservationLocked(gridID, key); r != nil {
+		return ErrCellReserved
+	}
 
 	g.Cells[key] = value
+	s.setOwnerLocked(gridID, key, owner)
 	return nil
 }
 
@@ -136,6 +338,7 @@ func (s *MemStore) ListCells(gridID string) ([]CellView, error) {
 		cells = append(cells, CellView{
 			Coord: coord,
 			Value: v,
+			Owner: s.owners[gridID][k],
 		})
 	}
 	return cells, nil
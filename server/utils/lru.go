@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a fixed-size, TTL-aware in-process cache. It is safe for
+// concurrent use.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	items   map[string]*list.Element
+	order   *list.List
+	hits    uint64
+	misses  uint64
+	evicted uint64
+}
+
+type lruEntry struct {
+	key       string
+	value     interface{}
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache holding at most size entries, each of which
+// expires ttl after it was last written. A ttl of 0 disables expiry.
+func NewLRU(size int, ttl time.Duration) *LRU {
+	if size <= 0 {
+		size = 1
+	}
+	return &LRU{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get returns the cached value for key, if present and not expired.
+func (c *LRU) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := el.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expiresAt) {
+		c.removeElement(el)
+		c.misses++
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	c.hits++
+	return entry.value, true
+}
+
+// Set stores value under key, evicting the least recently used entry if
+// the cache is full.
+func (c *LRU) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruEntry).value = value
+		el.Value.(*lruEntry).expiresAt = expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	el := c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	c.items[key] = el
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		if oldest != nil {
+			c.removeElement(oldest)
+			c.evicted++
+		}
+	}
+}
+
+// Delete evicts key, if present. It is a no-op otherwise.
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+func (c *LRU) removeElement(el *list.Element) {
+	entry := el.Value.(*lruEntry)
+	delete(c.items, entry.key)
+	c.order.Remove(el)
+}
+
+// Stats is a point-in-time snapshot of cache-hit metrics.
+type Stats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Evicted uint64 `json:"evicted"`
+	Len     int    `json:"len"`
+}
+
+// Stats returns cache-hit metrics accumulated since the cache was created.
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return Stats{
+		Hits:    c.hits,
+		Misses:  c.misses,
+		Evicted: c.evicted,
+		Len:     c.order.Len(),
+	}
+}